@@ -2,7 +2,9 @@ package webrtc
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/bluenviron/gortsplib/v4/pkg/format"
@@ -15,10 +17,19 @@ import (
 	"github.com/bluenviron/mediamtx/internal/logger"
 )
 
+// mimeTypeRTX and mimeTypeL16 are not defined as constants by the
+// pion/webrtc package.
 const (
-	keyFrameInterval = 2 * time.Second
+	mimeTypeRTX = "video/rtx"
+	mimeTypeL16 = "audio/L16"
 )
 
+// incomingVideoCodecs registers a "video/rtx" companion (apt=<PT>) next to
+// every video codec so NACK-driven retransmission can recover lost packets
+// on the ingest path. It does not register FlexFEC-03/ULPFEC: pinned
+// pion/interceptor only ships a FlexFEC encoder, not a decoder, so
+// registering those codecs here would advertise FEC recovery that never
+// actually happens.
 var incomingVideoCodecs = []webrtc.RTPCodecParameters{
 	{
 		RTPCodecCapability: webrtc.RTPCodecCapability{
@@ -28,6 +39,14 @@ var incomingVideoCodecs = []webrtc.RTPCodecParameters{
 		},
 		PayloadType: 96,
 	},
+	{
+		RTPCodecCapability: webrtc.RTPCodecCapability{
+			MimeType:    mimeTypeRTX,
+			ClockRate:   90000,
+			SDPFmtpLine: "apt=96",
+		},
+		PayloadType: 105,
+	},
 	{
 		RTPCodecCapability: webrtc.RTPCodecCapability{
 			MimeType:  webrtc.MimeTypeAV1,
@@ -35,6 +54,14 @@ var incomingVideoCodecs = []webrtc.RTPCodecParameters{
 		},
 		PayloadType: 97,
 	},
+	{
+		RTPCodecCapability: webrtc.RTPCodecCapability{
+			MimeType:    mimeTypeRTX,
+			ClockRate:   90000,
+			SDPFmtpLine: "apt=97",
+		},
+		PayloadType: 106,
+	},
 	{
 		RTPCodecCapability: webrtc.RTPCodecCapability{
 			MimeType:    webrtc.MimeTypeVP9,
@@ -43,6 +70,14 @@ var incomingVideoCodecs = []webrtc.RTPCodecParameters{
 		},
 		PayloadType: 98,
 	},
+	{
+		RTPCodecCapability: webrtc.RTPCodecCapability{
+			MimeType:    mimeTypeRTX,
+			ClockRate:   90000,
+			SDPFmtpLine: "apt=98",
+		},
+		PayloadType: 107,
+	},
 	{
 		RTPCodecCapability: webrtc.RTPCodecCapability{
 			MimeType:    webrtc.MimeTypeVP9,
@@ -51,6 +86,14 @@ var incomingVideoCodecs = []webrtc.RTPCodecParameters{
 		},
 		PayloadType: 99,
 	},
+	{
+		RTPCodecCapability: webrtc.RTPCodecCapability{
+			MimeType:    mimeTypeRTX,
+			ClockRate:   90000,
+			SDPFmtpLine: "apt=99",
+		},
+		PayloadType: 108,
+	},
 	{
 		RTPCodecCapability: webrtc.RTPCodecCapability{
 			MimeType:    webrtc.MimeTypeVP9,
@@ -59,6 +102,14 @@ var incomingVideoCodecs = []webrtc.RTPCodecParameters{
 		},
 		PayloadType: 100,
 	},
+	{
+		RTPCodecCapability: webrtc.RTPCodecCapability{
+			MimeType:    mimeTypeRTX,
+			ClockRate:   90000,
+			SDPFmtpLine: "apt=100",
+		},
+		PayloadType: 109,
+	},
 	{
 		RTPCodecCapability: webrtc.RTPCodecCapability{
 			MimeType:    webrtc.MimeTypeVP9,
@@ -67,6 +118,14 @@ var incomingVideoCodecs = []webrtc.RTPCodecParameters{
 		},
 		PayloadType: 101,
 	},
+	{
+		RTPCodecCapability: webrtc.RTPCodecCapability{
+			MimeType:    mimeTypeRTX,
+			ClockRate:   90000,
+			SDPFmtpLine: "apt=101",
+		},
+		PayloadType: 110,
+	},
 	{
 		RTPCodecCapability: webrtc.RTPCodecCapability{
 			MimeType:  webrtc.MimeTypeVP8,
@@ -74,6 +133,14 @@ var incomingVideoCodecs = []webrtc.RTPCodecParameters{
 		},
 		PayloadType: 102,
 	},
+	{
+		RTPCodecCapability: webrtc.RTPCodecCapability{
+			MimeType:    mimeTypeRTX,
+			ClockRate:   90000,
+			SDPFmtpLine: "apt=102",
+		},
+		PayloadType: 112,
+	},
 	{
 		RTPCodecCapability: webrtc.RTPCodecCapability{
 			MimeType:    webrtc.MimeTypeH264,
@@ -82,6 +149,14 @@ var incomingVideoCodecs = []webrtc.RTPCodecParameters{
 		},
 		PayloadType: 103,
 	},
+	{
+		RTPCodecCapability: webrtc.RTPCodecCapability{
+			MimeType:    mimeTypeRTX,
+			ClockRate:   90000,
+			SDPFmtpLine: "apt=103",
+		},
+		PayloadType: 113,
+	},
 	{
 		RTPCodecCapability: webrtc.RTPCodecCapability{
 			MimeType:    webrtc.MimeTypeH264,
@@ -90,6 +165,30 @@ var incomingVideoCodecs = []webrtc.RTPCodecParameters{
 		},
 		PayloadType: 104,
 	},
+	{
+		RTPCodecCapability: webrtc.RTPCodecCapability{
+			MimeType:    mimeTypeRTX,
+			ClockRate:   90000,
+			SDPFmtpLine: "apt=104",
+		},
+		PayloadType: 114,
+	},
+	{
+		RTPCodecCapability: webrtc.RTPCodecCapability{
+			MimeType:    webrtc.MimeTypeH265,
+			ClockRate:   90000,
+			SDPFmtpLine: "level-id=93;profile-id=1;tier-flag=0;tx-mode=SRST",
+		},
+		PayloadType: 117,
+	},
+	{
+		RTPCodecCapability: webrtc.RTPCodecCapability{
+			MimeType:    mimeTypeRTX,
+			ClockRate:   90000,
+			SDPFmtpLine: "apt=117",
+		},
+		PayloadType: 116,
+	},
 }
 
 var incomingAudioCodecs = []webrtc.RTPCodecParameters{
@@ -139,28 +238,95 @@ var incomingAudioCodecs = []webrtc.RTPCodecParameters{
 		},
 		PayloadType: 8,
 	},
+	{
+		RTPCodecCapability: webrtc.RTPCodecCapability{
+			MimeType:  mimeTypeL16,
+			ClockRate: 16000,
+			Channels:  1,
+		},
+		PayloadType: 121,
+	},
+	{
+		RTPCodecCapability: webrtc.RTPCodecCapability{
+			MimeType:  mimeTypeL16,
+			ClockRate: 48000,
+			Channels:  2,
+		},
+		PayloadType: 122,
+	},
+	{
+		RTPCodecCapability: webrtc.RTPCodecCapability{
+			MimeType:    webrtc.MimeTypeOpus,
+			ClockRate:   48000,
+			Channels:    6,
+			SDPFmtpLine: "minptime=10;useinbandfec=1;num_streams=4;coupled_streams=2;channel_mapping=0,4,1,2,3,5",
+		},
+		PayloadType: 123,
+	},
+}
+
+// opusChannelCount returns the number of Opus channels negotiated for a
+// track, supporting both stereo (RFC 7587) and multi-channel (RFC 8866
+// "num_streams"/"coupled_streams") fmtp conventions.
+func opusChannelCount(codec webrtc.RTPCodecParameters) int {
+	fmtp := codec.SDPFmtpLine
+
+	var numStreams, coupledStreams int
+	for _, kv := range strings.Split(fmtp, ";") {
+		kv = strings.TrimSpace(kv)
+
+		switch {
+		case strings.HasPrefix(kv, "num_streams="):
+			numStreams, _ = strconv.Atoi(strings.TrimPrefix(kv, "num_streams="))
+
+		case strings.HasPrefix(kv, "coupled_streams="):
+			coupledStreams, _ = strconv.Atoi(strings.TrimPrefix(kv, "coupled_streams="))
+		}
+	}
+
+	if numStreams != 0 {
+		return numStreams + coupledStreams
+	}
+
+	if strings.Contains(fmtp, "stereo=1") {
+		return 2
+	}
+
+	return 1
 }
 
 // IncomingTrack is an incoming track.
 type IncomingTrack struct {
-	track *webrtc.TrackRemote
-	log   logger.Writer
-
-	format    format.Format
-	reorderer *rtpreorderer.Reorderer
-	pkts      []*rtp.Packet
+	track                 *webrtc.TrackRemote
+	writeRTCP             func([]rtcp.Packet) error
+	keyFrameRequestPolicy KeyFrameRequestPolicy
+	log                   logger.Writer
+
+	format       format.Format
+	reorderer    *rtpreorderer.Reorderer
+	pkts         []*rtp.Packet
+	keyFrameChan chan struct{}
+	firSeqNum    uint8
+
+	statsMutex sync.Mutex
+	stats      TrackStats
+	onRTCP     []func(rtcp.Packet)
 }
 
 func newIncomingTrack(
 	track *webrtc.TrackRemote,
 	receiver *webrtc.RTPReceiver,
 	writeRTCP func([]rtcp.Packet) error,
+	keyFrameRequestPolicy *KeyFrameRequestPolicy,
 	log logger.Writer,
 ) (*IncomingTrack, error) {
 	t := &IncomingTrack{
-		track:     track,
-		log:       log,
-		reorderer: rtpreorderer.New(),
+		track:                 track,
+		writeRTCP:             writeRTCP,
+		keyFrameRequestPolicy: keyFrameRequestPolicy.unwrap(),
+		log:                   log,
+		reorderer:             rtpreorderer.New(),
+		keyFrameChan:          make(chan struct{}, 1),
 	}
 
 	isVideo := false
@@ -185,21 +351,43 @@ func newIncomingTrack(
 		}
 
 	case strings.ToLower(webrtc.MimeTypeH264):
+		if !negotiatedH264ProfileSupported(track.Codec().SDPFmtpLine) {
+			return nil, fmt.Errorf("unsupported H264 profile: %s", track.Codec().SDPFmtpLine)
+		}
+
 		isVideo = true
 		t.format = &format.H264{
 			PayloadTyp:        uint8(track.PayloadType()),
 			PacketizationMode: 1,
 		}
 
+	case strings.ToLower(webrtc.MimeTypeH265):
+		if !negotiatedH265ProfileSupported(track.Codec().SDPFmtpLine) {
+			return nil, fmt.Errorf("unsupported H265 profile: %s", track.Codec().SDPFmtpLine)
+		}
+
+		isVideo = true
+		t.format = &format.H265{
+			PayloadTyp: uint8(track.PayloadType()),
+		}
+
 	case strings.ToLower(webrtc.MimeTypeOpus):
 		t.format = &format.Opus{
-			PayloadTyp: uint8(track.PayloadType()),
-			ChannelCount: func() int {
-				if strings.Contains(track.Codec().SDPFmtpLine, "stereo=1") {
-					return 2
-				}
-				return 1
-			}(),
+			PayloadTyp:   uint8(track.PayloadType()),
+			ChannelCount: opusChannelCount(track.Codec()),
+		}
+
+	case strings.ToLower(mimeTypeL16):
+		channels := track.Codec().Channels
+		if channels == 0 {
+			channels = 1
+		}
+
+		t.format = &format.LPCM{
+			PayloadTyp:   uint8(track.PayloadType()),
+			BitDepth:     16,
+			SampleRate:   int(track.Codec().ClockRate),
+			ChannelCount: int(channels),
 		}
 
 	case strings.ToLower(webrtc.MimeTypeG722):
@@ -245,44 +433,131 @@ func newIncomingTrack(
 		return nil, fmt.Errorf("unsupported codec: %v", track.Codec())
 	}
 
-	// read incoming RTCP packets to make interceptors work
+	// read incoming RTCP packets to make interceptors work, update stats
+	// and watch for high fraction-lost so a key frame can be requested
+	// immediately instead of waiting for the periodic timer.
+	//
+	// pion hands every simulcast layer of the same MSID the same
+	// *RTPReceiver: Read always services the receiver's primary track, so a
+	// non-primary layer must demux its own RTCP through ReadSimulcast by RID
+	// instead, or it never sees any RTCP at all.
+	rid := track.RID()
+
 	go func() {
 		buf := make([]byte, 1500)
 		for {
-			_, _, err := receiver.Read(buf)
+			var n int
+			var err error
+			if rid != "" {
+				n, _, err = receiver.ReadSimulcast(buf, rid)
+			} else {
+				n, _, err = receiver.Read(buf)
+			}
 			if err != nil {
 				return
 			}
+
+			packets, err := rtcp.Unmarshal(buf[:n])
+			if err != nil {
+				continue
+			}
+
+			for _, pkt := range packets {
+				highLoss := t.processRTCP(pkt)
+				if isVideo && highLoss {
+					t.RequestKeyFrame()
+				}
+			}
 		}
 	}()
 
-	// send period key frame requests
+	// request key frames according to the configured policy: immediately
+	// when triggered by RequestKeyFrame() (itself triggered by reorderer
+	// losses, bad receiver reports or a downstream reader), otherwise fall
+	// back to the slow periodic timer.
 	if isVideo {
-		go func() {
-			keyframeTicker := time.NewTicker(keyFrameInterval)
-			defer keyframeTicker.Stop()
-
-			for range keyframeTicker.C {
-				err := writeRTCP([]rtcp.Packet{
-					&rtcp.PictureLossIndication{
-						MediaSSRC: uint32(t.track.SSRC()),
-					},
-				})
-				if err != nil {
-					return
-				}
-			}
-		}()
+		go t.runKeyFrameRequester()
 	}
 
 	return t, nil
 }
 
+// runKeyFrameRequester sends key frame requests to the publisher, either
+// because RequestKeyFrame() was called or because MaxInterval has elapsed
+// since the last request.
+func (t *IncomingTrack) runKeyFrameRequester() {
+	maxTimer := time.NewTimer(t.keyFrameRequestPolicy.MaxInterval)
+	defer maxTimer.Stop()
+
+	for {
+		select {
+		case <-t.keyFrameChan:
+			if !maxTimer.Stop() {
+				<-maxTimer.C
+			}
+
+		case <-maxTimer.C:
+		}
+
+		if err := t.sendKeyFrameRequest(); err != nil {
+			return
+		}
+
+		maxTimer.Reset(t.keyFrameRequestPolicy.MaxInterval)
+
+		// wait MinInterval before sending again. A request made meanwhile
+		// stays queued in keyFrameChan and is sent once MinInterval has
+		// passed.
+		time.Sleep(t.keyFrameRequestPolicy.MinInterval)
+	}
+}
+
+func (t *IncomingTrack) sendKeyFrameRequest() error {
+	var pkt rtcp.Packet
+
+	switch t.keyFrameRequestPolicy.Method {
+	case KeyFrameRequestMethodFIR:
+		t.firSeqNum++
+		pkt = &rtcp.FullIntraRequest{
+			MediaSSRC: uint32(t.track.SSRC()),
+			FIR: []rtcp.FIREntry{
+				{
+					SSRC:           uint32(t.track.SSRC()),
+					SequenceNumber: t.firSeqNum,
+				},
+			},
+		}
+
+	default:
+		pkt = &rtcp.PictureLossIndication{
+			MediaSSRC: uint32(t.track.SSRC()),
+		}
+	}
+
+	return t.writeRTCP([]rtcp.Packet{pkt})
+}
+
+// RequestKeyFrame asks the publisher to send a new key frame as soon as
+// possible. It can be called by the reorderer upon detecting losses, or by
+// a downstream reader (HLS/RTSP/WebRTC egress) that needs one right away.
+func (t *IncomingTrack) RequestKeyFrame() {
+	select {
+	case t.keyFrameChan <- struct{}{}:
+	default:
+	}
+}
+
 // Format returns the track format.
 func (t *IncomingTrack) Format() format.Format {
 	return t.format
 }
 
+// RID returns the RTP stream identifier of this track, as assigned by the
+// publisher's SDP "a=rid" attribute. It is empty for non-simulcast tracks.
+func (t *IncomingTrack) RID() string {
+	return t.track.RID()
+}
+
 // ReadRTP reads a RTP packet.
 func (t *IncomingTrack) ReadRTP() (*rtp.Packet, error) {
 	for {
@@ -308,6 +583,10 @@ func (t *IncomingTrack) ReadRTP() (*rtp.Packet, error) {
 		if lost != 0 {
 			t.log.Log(logger.Warn, (liberrors.ErrClientRTPPacketsLost{Lost: lost}).Error())
 			// do not return
+
+			if lost >= lostPacketsKeyFrameThreshold {
+				t.RequestKeyFrame()
+			}
 		}
 
 		if len(t.pkts) == 0 {
@@ -0,0 +1,126 @@
+package webrtc
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pion/webrtc/v3"
+)
+
+// sdpVideoRejected returns whether an SDP answer rejected the video media
+// section (port 0), as RFC 3264 requires when no common codec was found.
+func sdpVideoRejected(t *testing.T, sdp string) bool {
+	for _, line := range strings.Split(sdp, "\r\n") {
+		if strings.HasPrefix(line, "m=video ") {
+			fields := strings.Fields(line)
+			if len(fields) < 2 {
+				t.Fatalf("malformed m=video line: %q", line)
+			}
+			return fields[1] == "0"
+		}
+	}
+
+	t.Fatal("no m=video line found in SDP")
+	return false
+}
+
+func negotiate(t *testing.T, offerEngine, answerEngine *webrtc.MediaEngine) string {
+	pcOffer, err := webrtc.NewAPI(webrtc.WithMediaEngine(offerEngine)).NewPeerConnection(webrtc.Configuration{})
+	if err != nil {
+		t.Fatalf("NewPeerConnection(offer): %v", err)
+	}
+	defer pcOffer.Close() //nolint:errcheck
+
+	_, err = pcOffer.AddTransceiverFromKind(webrtc.RTPCodecTypeVideo, webrtc.RTPTransceiverInit{
+		Direction: webrtc.RTPTransceiverDirectionSendonly,
+	})
+	if err != nil {
+		t.Fatalf("AddTransceiverFromKind: %v", err)
+	}
+
+	offer, err := pcOffer.CreateOffer(nil)
+	if err != nil {
+		t.Fatalf("CreateOffer: %v", err)
+	}
+
+	err = pcOffer.SetLocalDescription(offer)
+	if err != nil {
+		t.Fatalf("SetLocalDescription(offer): %v", err)
+	}
+
+	pcAnswer, err := webrtc.NewAPI(webrtc.WithMediaEngine(answerEngine)).NewPeerConnection(webrtc.Configuration{})
+	if err != nil {
+		t.Fatalf("NewPeerConnection(answer): %v", err)
+	}
+	defer pcAnswer.Close() //nolint:errcheck
+
+	err = pcAnswer.SetRemoteDescription(offer)
+	if err != nil {
+		t.Fatalf("SetRemoteDescription: %v", err)
+	}
+
+	answer, err := pcAnswer.CreateAnswer(nil)
+	if err != nil {
+		t.Fatalf("CreateAnswer: %v", err)
+	}
+
+	err = pcAnswer.SetLocalDescription(answer)
+	if err != nil {
+		t.Fatalf("SetLocalDescription(answer): %v", err)
+	}
+
+	return answer.SDP
+}
+
+// TestIncomingVideoCodecsSDPRoundTripMismatch verifies that a publisher
+// offering a video codec we do not support at all is cleanly rejected in the
+// SDP answer (m=video port 0) instead of being silently accepted and ending
+// up as a muted track. Pion matches codecs on MIME type alone once an exact
+// fmtp match fails (see webrtc.codecParametersFuzzySearch), so an unknown
+// MIME type is the only reliable way to force a mismatch here.
+func TestIncomingVideoCodecsSDPRoundTripMismatch(t *testing.T) {
+	offerEngine := &webrtc.MediaEngine{}
+	err := offerEngine.RegisterCodec(webrtc.RTPCodecParameters{
+		RTPCodecCapability: webrtc.RTPCodecCapability{
+			MimeType:    "video/x-unsupported",
+			ClockRate:   90000,
+			SDPFmtpLine: "",
+		},
+		PayloadType: 125,
+	}, webrtc.RTPCodecTypeVideo)
+	if err != nil {
+		t.Fatalf("RegisterCodec: %v", err)
+	}
+
+	answerEngine, err := newMediaEngine()
+	if err != nil {
+		t.Fatalf("newMediaEngine: %v", err)
+	}
+
+	sdp := negotiate(t, offerEngine, answerEngine)
+
+	if !sdpVideoRejected(t, sdp) {
+		t.Fatalf("expected unsupported codec to be rejected, got SDP:\n%s", sdp)
+	}
+}
+
+// TestIncomingVideoCodecsSDPRoundTripSupported is the positive counterpart
+// of TestIncomingVideoCodecsSDPRoundTripMismatch: when both sides support
+// the same codec set, the video section must be accepted.
+func TestIncomingVideoCodecsSDPRoundTripSupported(t *testing.T) {
+	offerEngine, err := newMediaEngine()
+	if err != nil {
+		t.Fatalf("newMediaEngine: %v", err)
+	}
+
+	answerEngine, err := newMediaEngine()
+	if err != nil {
+		t.Fatalf("newMediaEngine: %v", err)
+	}
+
+	sdp := negotiate(t, offerEngine, answerEngine)
+
+	if sdpVideoRejected(t, sdp) {
+		t.Fatalf("expected supported codec set to be accepted, got SDP:\n%s", sdp)
+	}
+}
@@ -0,0 +1,63 @@
+package webrtc
+
+import "time"
+
+// KeyFrameRequestMethod is a method for requesting a key frame from a publisher.
+type KeyFrameRequestMethod int
+
+// key frame request methods.
+const (
+	KeyFrameRequestMethodPLI KeyFrameRequestMethod = iota
+	KeyFrameRequestMethodFIR
+)
+
+const (
+	defaultKeyFrameMinInterval = 500 * time.Millisecond
+	defaultKeyFrameMaxInterval = 2 * time.Second
+
+	// lostPacketsKeyFrameThreshold is the minimum amount of packets lost, as
+	// reported by the reorderer, that triggers an immediate key frame request.
+	lostPacketsKeyFrameThreshold = 1
+
+	// fractionLostKeyFrameThreshold is the minimum RTCP receiver-report
+	// fraction-lost (0-255, where 255 is 100%) that triggers an immediate
+	// key frame request.
+	fractionLostKeyFrameThreshold = 25
+)
+
+// KeyFrameRequestPolicy configures how and how often key frames are
+// requested from a publisher of a video track.
+type KeyFrameRequestPolicy struct {
+	// Method is the RTCP feedback message used to request a key frame.
+	// It defaults to KeyFrameRequestMethodPLI.
+	Method KeyFrameRequestMethod
+
+	// MinInterval is the minimum interval between two consecutive key frame
+	// requests, used to avoid flooding the publisher when losses are
+	// reported in bursts. It defaults to 500ms.
+	MinInterval time.Duration
+
+	// MaxInterval is the interval used to request a key frame when the
+	// stream is healthy, as a fallback in case a decoder needs to recover
+	// without any loss being reported. It defaults to 2s.
+	MaxInterval time.Duration
+}
+
+func (p *KeyFrameRequestPolicy) unwrap() KeyFrameRequestPolicy {
+	if p != nil {
+		out := *p
+		if out.MinInterval == 0 {
+			out.MinInterval = defaultKeyFrameMinInterval
+		}
+		if out.MaxInterval == 0 {
+			out.MaxInterval = defaultKeyFrameMaxInterval
+		}
+		return out
+	}
+
+	return KeyFrameRequestPolicy{
+		Method:      KeyFrameRequestMethodPLI,
+		MinInterval: defaultKeyFrameMinInterval,
+		MaxInterval: defaultKeyFrameMaxInterval,
+	}
+}
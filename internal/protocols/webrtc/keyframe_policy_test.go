@@ -0,0 +1,52 @@
+package webrtc
+
+import (
+	"testing"
+	"time"
+)
+
+func TestKeyFrameRequestPolicyUnwrap(t *testing.T) {
+	for _, ca := range []struct {
+		name string
+		in   *KeyFrameRequestPolicy
+		want KeyFrameRequestPolicy
+	}{
+		{
+			"nil uses all defaults",
+			nil,
+			KeyFrameRequestPolicy{
+				Method:      KeyFrameRequestMethodPLI,
+				MinInterval: defaultKeyFrameMinInterval,
+				MaxInterval: defaultKeyFrameMaxInterval,
+			},
+		},
+		{
+			"zero value fills in interval defaults",
+			&KeyFrameRequestPolicy{Method: KeyFrameRequestMethodFIR},
+			KeyFrameRequestPolicy{
+				Method:      KeyFrameRequestMethodFIR,
+				MinInterval: defaultKeyFrameMinInterval,
+				MaxInterval: defaultKeyFrameMaxInterval,
+			},
+		},
+		{
+			"explicit intervals are preserved",
+			&KeyFrameRequestPolicy{
+				Method:      KeyFrameRequestMethodFIR,
+				MinInterval: time.Second,
+				MaxInterval: 10 * time.Second,
+			},
+			KeyFrameRequestPolicy{
+				Method:      KeyFrameRequestMethodFIR,
+				MinInterval: time.Second,
+				MaxInterval: 10 * time.Second,
+			},
+		},
+	} {
+		t.Run(ca.name, func(t *testing.T) {
+			if got := ca.in.unwrap(); got != ca.want {
+				t.Errorf("unwrap() = %+v, want %+v", got, ca.want)
+			}
+		})
+	}
+}
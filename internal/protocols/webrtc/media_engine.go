@@ -0,0 +1,134 @@
+package webrtc
+
+import (
+	"strings"
+
+	"github.com/pion/interceptor"
+	"github.com/pion/webrtc/v3"
+)
+
+// newMediaEngine creates a MediaEngine with all incoming codecs (including
+// their RTX companions) registered. It does not register any interceptor;
+// NewAPI wires the NACK interceptors on top of it.
+func newMediaEngine() (*webrtc.MediaEngine, error) {
+	m := &webrtc.MediaEngine{}
+
+	for _, codec := range incomingVideoCodecs {
+		err := m.RegisterCodec(codec, webrtc.RTPCodecTypeVideo)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, codec := range incomingAudioCodecs {
+		err := m.RegisterCodec(codec, webrtc.RTPCodecTypeAudio)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+// fmtpParam returns the value of a single "key=value" fmtp parameter, or
+// false if it is not present.
+func fmtpParam(fmtpLine, key string) (string, bool) {
+	for _, kv := range strings.Split(fmtpLine, ";") {
+		kv = strings.TrimSpace(kv)
+		if strings.HasPrefix(kv, key+"=") {
+			return strings.TrimPrefix(kv, key+"="), true
+		}
+	}
+
+	return "", false
+}
+
+// negotiatedH264ProfileSupported reports whether the profile-level-id that
+// was actually negotiated for a H264 TrackRemote matches one of the
+// profiles incomingVideoCodecs registers. Only profile_idc and the
+// constraint flags (the first two bytes of profile-level-id) are compared,
+// not the level, matching RFC 6184 Section 8.2.2 and pion's own fmtp
+// matching: a publisher can still end up with a track whose profile pion's
+// fuzzy codec matching accepted but that we never registered (pion falls
+// back to matching on MIME type alone once no registered codec has an
+// exact fmtp match), so this is checked again here before the track is
+// built.
+func negotiatedH264ProfileSupported(fmtpLine string) bool {
+	remote, ok := fmtpParam(fmtpLine, "profile-level-id")
+	if !ok || len(remote) < 4 {
+		return false
+	}
+
+	for _, codec := range incomingVideoCodecs {
+		if !strings.EqualFold(codec.MimeType, webrtc.MimeTypeH264) {
+			continue
+		}
+
+		local, ok := fmtpParam(codec.SDPFmtpLine, "profile-level-id")
+		if ok && len(local) >= 4 && strings.EqualFold(remote[:4], local[:4]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// negotiatedH265ProfileSupported is the H265 counterpart of
+// negotiatedH264ProfileSupported, comparing profile-id and tier-flag (not
+// level-id, for the same reason those are excluded above).
+func negotiatedH265ProfileSupported(fmtpLine string) bool {
+	remoteProfile, ok := fmtpParam(fmtpLine, "profile-id")
+	if !ok {
+		return false
+	}
+	remoteTier, _ := fmtpParam(fmtpLine, "tier-flag")
+
+	for _, codec := range incomingVideoCodecs {
+		if !strings.EqualFold(codec.MimeType, webrtc.MimeTypeH265) {
+			continue
+		}
+
+		localProfile, ok := fmtpParam(codec.SDPFmtpLine, "profile-id")
+		if !ok || localProfile != remoteProfile {
+			continue
+		}
+
+		localTier, _ := fmtpParam(codec.SDPFmtpLine, "tier-flag")
+		if localTier == remoteTier {
+			return true
+		}
+	}
+
+	return false
+}
+
+// registerInterceptors registers the NACK generator and responder (so lost
+// packets are requested and retransmitted through RTX) into the given
+// interceptor registry.
+func registerInterceptors(m *webrtc.MediaEngine, i *interceptor.Registry) error {
+	return webrtc.ConfigureNack(m, i)
+}
+
+// NewAPI builds the pion webrtc.API that a WebRTC server should use to
+// create publisher PeerConnections: it registers incomingVideoCodecs and
+// incomingAudioCodecs (including their RTX companions) on a fresh
+// MediaEngine and wires the NACK interceptor on top, so that RTX-based
+// retransmission works end-to-end on the ingest path.
+func NewAPI() (*webrtc.API, error) {
+	m, err := newMediaEngine()
+	if err != nil {
+		return nil, err
+	}
+
+	i := &interceptor.Registry{}
+
+	err = registerInterceptors(m, i)
+	if err != nil {
+		return nil, err
+	}
+
+	return webrtc.NewAPI(
+		webrtc.WithMediaEngine(m),
+		webrtc.WithInterceptorRegistry(i),
+	), nil
+}
@@ -0,0 +1,81 @@
+package webrtc
+
+import (
+	"testing"
+)
+
+func TestNegotiatedH264ProfileSupported(t *testing.T) {
+	for _, ca := range []struct {
+		name     string
+		fmtpLine string
+		supp     bool
+	}{
+		{
+			"registered baseline profile",
+			"level-asymmetry-allowed=1;packetization-mode=1;profile-level-id=42001f",
+			true,
+		},
+		{
+			"registered constrained baseline profile, different level",
+			"level-asymmetry-allowed=1;packetization-mode=1;profile-level-id=42e00a",
+			true,
+		},
+		{
+			"unregistered high profile",
+			"level-asymmetry-allowed=1;packetization-mode=1;profile-level-id=4d001f",
+			false,
+		},
+		{
+			"missing profile-level-id",
+			"level-asymmetry-allowed=1;packetization-mode=1",
+			false,
+		},
+	} {
+		t.Run(ca.name, func(t *testing.T) {
+			if supp := negotiatedH264ProfileSupported(ca.fmtpLine); supp != ca.supp {
+				t.Errorf("negotiatedH264ProfileSupported(%q) = %v, want %v", ca.fmtpLine, supp, ca.supp)
+			}
+		})
+	}
+}
+
+func TestNegotiatedH265ProfileSupported(t *testing.T) {
+	for _, ca := range []struct {
+		name     string
+		fmtpLine string
+		supp     bool
+	}{
+		{
+			"registered profile and tier",
+			"level-id=150;profile-id=1;tier-flag=0;tx-mode=SRST",
+			true,
+		},
+		{
+			"unregistered profile",
+			"level-id=93;profile-id=2;tier-flag=0;tx-mode=SRST",
+			false,
+		},
+		{
+			"registered profile, mismatched tier",
+			"level-id=93;profile-id=1;tier-flag=1;tx-mode=SRST",
+			false,
+		},
+		{
+			"missing profile-id",
+			"level-id=93;tier-flag=0;tx-mode=SRST",
+			false,
+		},
+	} {
+		t.Run(ca.name, func(t *testing.T) {
+			if supp := negotiatedH265ProfileSupported(ca.fmtpLine); supp != ca.supp {
+				t.Errorf("negotiatedH265ProfileSupported(%q) = %v, want %v", ca.fmtpLine, supp, ca.supp)
+			}
+		})
+	}
+}
+
+func TestNewMediaEngine(t *testing.T) {
+	if _, err := newMediaEngine(); err != nil {
+		t.Fatalf("newMediaEngine: %v", err)
+	}
+}
@@ -0,0 +1,334 @@
+package webrtc
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pion/rtcp"
+)
+
+// SimulcastLayer is a single spatial/quality layer of a simulcast publisher,
+// backed by its own IncomingTrack.
+type SimulcastLayer struct {
+	// RID is the RTP stream identifier that pion uses to distinguish the
+	// simulcast layers of a single MSID.
+	RID string
+
+	// Track is the incoming track carrying this layer.
+	Track *IncomingTrack
+
+	// Width and Height are the maximum resolution advertised for this layer
+	// in the publisher's SDP "a=rid" attribute ("max-width"/"max-height").
+	// They are 0 when the publisher did not advertise them.
+	Width, Height int
+
+	// TargetBitrate is the bitrate, in bits per second, that the publisher
+	// advertised for this layer in its SDP "a=rid" attribute ("max-br",
+	// in kbps). It is used to rank layers for ABR selection.
+	TargetBitrate uint64
+}
+
+// SimulcastLayerHint describes a simulcast layer as advertised by the
+// publisher's SDP offer, before the matching TrackRemote/IncomingTrack has
+// arrived.
+type SimulcastLayerHint struct {
+	RID           string
+	Width, Height int
+	TargetBitrate uint64
+}
+
+// ParseSimulcastHints extracts the RID, resolution and target bitrate of
+// each simulcast layer from the attribute lines ("a=...") of a single SDP
+// video media section. It understands the per-layer "a=rid:<rid> send
+// <params>" attribute and uses "a=simulcast:send <rid>;<rid>;..." only to
+// order the returned layers, as browsers do not guarantee "a=rid" lines
+// appear in bitrate order.
+func ParseSimulcastHints(sdpAttributeLines []string) []SimulcastLayerHint {
+	hints := map[string]*SimulcastLayerHint{}
+	var order []string
+
+	for _, line := range sdpAttributeLines {
+		line = strings.TrimSpace(line)
+
+		switch {
+		case strings.HasPrefix(line, "a=rid:"):
+			fields := strings.Fields(strings.TrimPrefix(line, "a=rid:"))
+			if len(fields) == 0 {
+				continue
+			}
+
+			rid := fields[0]
+
+			h, ok := hints[rid]
+			if !ok {
+				h = &SimulcastLayerHint{RID: rid}
+				hints[rid] = h
+				order = append(order, rid)
+			}
+
+			for _, param := range fields[1:] {
+				for _, kv := range strings.Split(param, ";") {
+					applyRIDParam(h, kv)
+				}
+			}
+
+		case strings.HasPrefix(line, "a=simulcast:"):
+			fields := strings.Fields(strings.TrimPrefix(line, "a=simulcast:"))
+			if len(fields) < 2 {
+				continue
+			}
+
+			var ridOrder []string
+			for _, rid := range strings.FieldsFunc(fields[1], func(r rune) bool {
+				return r == ';' || r == ','
+			}) {
+				ridOrder = append(ridOrder, strings.TrimPrefix(rid, "~"))
+			}
+
+			if len(ridOrder) > 0 {
+				order = ridOrder
+			}
+		}
+	}
+
+	out := make([]SimulcastLayerHint, 0, len(order))
+	for _, rid := range order {
+		if h, ok := hints[rid]; ok {
+			out = append(out, *h)
+		}
+	}
+
+	return out
+}
+
+func applyRIDParam(h *SimulcastLayerHint, kv string) {
+	parts := strings.SplitN(strings.TrimSpace(kv), "=", 2)
+	if len(parts) != 2 {
+		return
+	}
+
+	switch parts[0] {
+	case "max-width":
+		h.Width, _ = strconv.Atoi(parts[1])
+
+	case "max-height":
+		h.Height, _ = strconv.Atoi(parts[1])
+
+	case "max-br":
+		if kbps, err := strconv.Atoi(parts[1]); err == nil {
+			h.TargetBitrate = uint64(kbps) * 1000
+		}
+	}
+}
+
+// NewSimulcastGroupFromHints builds a SimulcastGroup out of the
+// IncomingTracks that pion delivered for a single simulcast-enabled MSID,
+// attaching to each one the RID/resolution/target-bitrate metadata that
+// ParseSimulcastHints extracted from the publisher's SDP offer.
+func NewSimulcastGroupFromHints(tracks []*IncomingTrack, hints []SimulcastLayerHint) *SimulcastGroup {
+	byRID := make(map[string]SimulcastLayerHint, len(hints))
+	for _, h := range hints {
+		byRID[h.RID] = h
+	}
+
+	g := NewSimulcastGroup()
+
+	for _, track := range tracks {
+		h := byRID[track.RID()]
+
+		g.AddLayer(&SimulcastLayer{
+			RID:           track.RID(),
+			Track:         track,
+			Width:         h.Width,
+			Height:        h.Height,
+			TargetBitrate: h.TargetBitrate,
+		})
+	}
+
+	return g
+}
+
+// SimulcastGroup groups the IncomingTracks that pion delivers as separate
+// TrackRemotes for the same simulcast-enabled MSID, and lets a downstream
+// consumer pick a layer explicitly or let it be chosen automatically based
+// on congestion feedback.
+type SimulcastGroup struct {
+	mu             sync.Mutex
+	layers         []*SimulcastLayer
+	selected       string
+	explicit       bool
+	auto           bool
+	onLayerChanged []func(rid string)
+}
+
+// NewSimulcastGroup creates an empty SimulcastGroup.
+func NewSimulcastGroup() *SimulcastGroup {
+	return &SimulcastGroup{
+		auto: true,
+	}
+}
+
+// AddLayer registers a layer of the simulcast group. Until SelectLayer is
+// called explicitly, the lowest-bitrate layer is kept selected, matching the
+// conservative start that ABR schemes rely on. If the publisher ever sends
+// REMB inbound on the layer's track, that feedback is used to drive
+// automatic layer selection (see evaluateCongestion); since REMB is
+// conventionally sent the other way, most publishers will not, and the
+// group then simply stays on the lowest-bitrate layer.
+func (g *SimulcastGroup) AddLayer(layer *SimulcastLayer) {
+	g.mu.Lock()
+
+	g.layers = append(g.layers, layer)
+	sort.Slice(g.layers, func(i, j int) bool {
+		return g.layers[i].TargetBitrate < g.layers[j].TargetBitrate
+	})
+
+	if !g.explicit {
+		g.selected = g.layers[0].RID
+	}
+
+	g.mu.Unlock()
+
+	layer.Track.OnRTCP(func(pkt rtcp.Packet) {
+		if _, ok := pkt.(*rtcp.ReceiverEstimatedMaximumBitrate); ok {
+			g.evaluateCongestion(layer.RID)
+		}
+	})
+}
+
+// SelectLayer switches the group to the layer with the given RID, disabling
+// automatic selection, and requests a key frame on it so the downstream
+// consumer can start decoding immediately.
+func (g *SimulcastGroup) SelectLayer(rid string) error {
+	g.mu.Lock()
+
+	var found *SimulcastLayer
+	for _, l := range g.layers {
+		if l.RID == rid {
+			found = l
+			break
+		}
+	}
+
+	if found == nil {
+		g.mu.Unlock()
+		return fmt.Errorf("simulcast layer not found: %s", rid)
+	}
+
+	g.auto = false
+	g.explicit = true
+	g.selected = rid
+
+	g.mu.Unlock()
+
+	found.Track.RequestKeyFrame()
+
+	g.notifyLayerChanged(rid)
+
+	return nil
+}
+
+// EnableAutoSelect re-enables automatic, congestion-driven layer selection.
+func (g *SimulcastGroup) EnableAutoSelect() {
+	g.mu.Lock()
+	g.auto = true
+	g.mu.Unlock()
+}
+
+// Selected returns the RID and track of the currently selected layer.
+func (g *SimulcastGroup) Selected() (string, *IncomingTrack) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for _, l := range g.layers {
+		if l.RID == g.selected {
+			return l.RID, l.Track
+		}
+	}
+
+	return "", nil
+}
+
+// OnLayerChanged registers a callback invoked whenever the selected layer
+// changes, either explicitly through SelectLayer or automatically through
+// congestion-driven downshift/upshift.
+func (g *SimulcastGroup) OnLayerChanged(cb func(rid string)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.onLayerChanged = append(g.onLayerChanged, cb)
+}
+
+func (g *SimulcastGroup) notifyLayerChanged(rid string) {
+	g.mu.Lock()
+	subscribers := make([]func(string), len(g.onLayerChanged))
+	copy(subscribers, g.onLayerChanged)
+	g.mu.Unlock()
+
+	for _, cb := range subscribers {
+		cb(rid)
+	}
+}
+
+// evaluateCongestion reacts to a REMB bitrate estimate reported on
+// reportingRID by downshifting to the next lower layer when the estimate no
+// longer covers the selected layer's target bitrate, or upshifting when it
+// covers the next higher one. It is a no-op when automatic selection is
+// disabled or the estimate did not come from the currently selected layer.
+// It only runs at all if the publisher sends REMB inbound, which most will
+// not (see AddLayer); without it, the group stays on whichever layer was
+// last selected.
+func (g *SimulcastGroup) evaluateCongestion(reportingRID string) {
+	g.mu.Lock()
+
+	if !g.auto || reportingRID != g.selected {
+		g.mu.Unlock()
+		return
+	}
+
+	var current, lower, higher *SimulcastLayer
+	for i, l := range g.layers {
+		if l.RID == g.selected {
+			current = l
+			if i > 0 {
+				lower = g.layers[i-1]
+			}
+			if i+1 < len(g.layers) {
+				higher = g.layers[i+1]
+			}
+			break
+		}
+	}
+
+	if current == nil {
+		g.mu.Unlock()
+		return
+	}
+
+	estimate := current.Track.Stats().BitrateEstimate
+
+	// a TargetBitrate of 0 means the publisher did not advertise one for
+	// that layer (no SDP bitrate hint); treat it as unknown rather than as
+	// "always satisfied", or the group would latch onto the highest layer
+	// on the first REMB report and never be able to downshift again.
+	next := current
+	if current.TargetBitrate != 0 && estimate < current.TargetBitrate && lower != nil {
+		next = lower
+	} else if higher != nil && higher.TargetBitrate != 0 && estimate >= higher.TargetBitrate {
+		next = higher
+	}
+
+	changed := next != current
+	if changed {
+		g.selected = next.RID
+	}
+
+	g.mu.Unlock()
+
+	if changed {
+		next.Track.RequestKeyFrame()
+		g.notifyLayerChanged(next.RID)
+	}
+}
@@ -0,0 +1,149 @@
+package webrtc
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSimulcastHints(t *testing.T) {
+	for _, ca := range []struct {
+		name  string
+		lines []string
+		want  []SimulcastLayerHint
+	}{
+		{
+			"rid params and simulcast ordering",
+			[]string{
+				"a=rid:q send pt=96;max-width=320;max-height=240;max-br=300",
+				"a=rid:h send pt=96;max-width=640;max-height=480;max-br=900",
+				"a=rid:f send pt=96;max-width=1280;max-height=720;max-br=2500",
+				"a=simulcast:send f;h;q",
+			},
+			[]SimulcastLayerHint{
+				{RID: "f", Width: 1280, Height: 720, TargetBitrate: 2_500_000},
+				{RID: "h", Width: 640, Height: 480, TargetBitrate: 900_000},
+				{RID: "q", Width: 320, Height: 240, TargetBitrate: 300_000},
+			},
+		},
+		{
+			"no a=simulcast falls back to a=rid appearance order",
+			[]string{
+				"a=rid:q send pt=96;max-br=300",
+				"a=rid:h send pt=96;max-br=900",
+			},
+			[]SimulcastLayerHint{
+				{RID: "q", TargetBitrate: 300_000},
+				{RID: "h", TargetBitrate: 900_000},
+			},
+		},
+		{
+			"no simulcast lines at all",
+			[]string{"a=sendrecv"},
+			[]SimulcastLayerHint{},
+		},
+	} {
+		t.Run(ca.name, func(t *testing.T) {
+			got := ParseSimulcastHints(ca.lines)
+			if !reflect.DeepEqual(got, ca.want) {
+				t.Errorf("ParseSimulcastHints() = %+v, want %+v", got, ca.want)
+			}
+		})
+	}
+}
+
+func newTestIncomingTrack(bitrateEstimate uint64) *IncomingTrack {
+	return &IncomingTrack{
+		keyFrameChan: make(chan struct{}, 1),
+		stats:        TrackStats{BitrateEstimate: bitrateEstimate},
+	}
+}
+
+func TestSimulcastGroupAddLayerSelectsLowestBitrate(t *testing.T) {
+	g := NewSimulcastGroup()
+
+	g.AddLayer(&SimulcastLayer{RID: "h", Track: newTestIncomingTrack(0), TargetBitrate: 900_000})
+	g.AddLayer(&SimulcastLayer{RID: "q", Track: newTestIncomingTrack(0), TargetBitrate: 300_000})
+	g.AddLayer(&SimulcastLayer{RID: "f", Track: newTestIncomingTrack(0), TargetBitrate: 2_500_000})
+
+	if rid, _ := g.Selected(); rid != "q" {
+		t.Errorf("Selected() = %q, want %q", rid, "q")
+	}
+}
+
+func TestSimulcastGroupSelectLayer(t *testing.T) {
+	g := NewSimulcastGroup()
+	g.AddLayer(&SimulcastLayer{RID: "q", Track: newTestIncomingTrack(0), TargetBitrate: 300_000})
+	g.AddLayer(&SimulcastLayer{RID: "f", Track: newTestIncomingTrack(0), TargetBitrate: 2_500_000})
+
+	var changedTo []string
+	g.OnLayerChanged(func(rid string) { changedTo = append(changedTo, rid) })
+
+	if err := g.SelectLayer("f"); err != nil {
+		t.Fatalf("SelectLayer: %v", err)
+	}
+
+	if rid, _ := g.Selected(); rid != "f" {
+		t.Errorf("Selected() = %q, want %q", rid, "f")
+	}
+	if !reflect.DeepEqual(changedTo, []string{"f"}) {
+		t.Errorf("onLayerChanged callbacks = %v, want %v", changedTo, []string{"f"})
+	}
+
+	if err := g.SelectLayer("nonexistent"); err == nil {
+		t.Error("SelectLayer(nonexistent) should return an error")
+	}
+}
+
+func TestSimulcastGroupEvaluateCongestion(t *testing.T) {
+	t.Run("downshifts when estimate no longer covers the selected layer", func(t *testing.T) {
+		g := NewSimulcastGroup()
+		g.AddLayer(&SimulcastLayer{RID: "q", Track: newTestIncomingTrack(0), TargetBitrate: 300_000})
+		g.AddLayer(&SimulcastLayer{RID: "f", Track: newTestIncomingTrack(200_000), TargetBitrate: 2_500_000})
+
+		g.SelectLayer("f")
+		g.EnableAutoSelect()
+
+		g.evaluateCongestion("f")
+
+		if rid, _ := g.Selected(); rid != "q" {
+			t.Errorf("Selected() = %q, want %q", rid, "q")
+		}
+	})
+
+	t.Run("upshifts when estimate covers the next higher layer", func(t *testing.T) {
+		g := NewSimulcastGroup()
+		g.AddLayer(&SimulcastLayer{RID: "q", Track: newTestIncomingTrack(3_000_000), TargetBitrate: 300_000})
+		g.AddLayer(&SimulcastLayer{RID: "f", Track: newTestIncomingTrack(0), TargetBitrate: 2_500_000})
+
+		g.evaluateCongestion("q")
+
+		if rid, _ := g.Selected(); rid != "f" {
+			t.Errorf("Selected() = %q, want %q", rid, "f")
+		}
+	})
+
+	t.Run("ignores reports once auto selection is disabled", func(t *testing.T) {
+		g := NewSimulcastGroup()
+		g.AddLayer(&SimulcastLayer{RID: "q", Track: newTestIncomingTrack(0), TargetBitrate: 300_000})
+		g.AddLayer(&SimulcastLayer{RID: "f", Track: newTestIncomingTrack(200_000), TargetBitrate: 2_500_000})
+
+		g.SelectLayer("f")
+		g.evaluateCongestion("f")
+
+		if rid, _ := g.Selected(); rid != "f" {
+			t.Errorf("Selected() = %q, want %q (explicit selection should not be overridden)", rid, "f")
+		}
+	})
+
+	t.Run("ignores a report from a layer that is not currently selected", func(t *testing.T) {
+		g := NewSimulcastGroup()
+		g.AddLayer(&SimulcastLayer{RID: "q", Track: newTestIncomingTrack(0), TargetBitrate: 300_000})
+		g.AddLayer(&SimulcastLayer{RID: "f", Track: newTestIncomingTrack(0), TargetBitrate: 2_500_000})
+
+		g.evaluateCongestion("f")
+
+		if rid, _ := g.Selected(); rid != "q" {
+			t.Errorf("Selected() = %q, want %q", rid, "q")
+		}
+	})
+}
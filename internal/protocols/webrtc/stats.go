@@ -0,0 +1,121 @@
+package webrtc
+
+import (
+	"time"
+
+	"github.com/pion/rtcp"
+)
+
+// TrackStats holds the latest RTCP-derived quality metrics for an incoming
+// track, as reported by sender/receiver reports and, opportunistically, by
+// congestion feedback (REMB, TWCC) received from the remote peer. REMB and
+// TWCC are conventionally generated by the media *receiver* (mediamtx, on
+// this ingest path) for the sender to act on, so a well-behaved publisher
+// has no reason to send either inbound; BitrateEstimate is only populated
+// if one does anyway.
+type TrackStats struct {
+	// FractionLost is the fraction of RTP packets lost since the previous
+	// report, expressed as a value between 0 and 255 (255 = 100%).
+	FractionLost uint8
+
+	// PacketsLost is the cumulative number of RTP packets lost.
+	PacketsLost uint32
+
+	// Jitter is the interarrival jitter, in RTP timestamp units.
+	Jitter uint32
+
+	// LastSenderReport is the time at which the last RTCP sender report
+	// was received.
+	LastSenderReport time.Time
+
+	// BitrateEstimate is the latest bandwidth estimate for this track, as
+	// reported through REMB or TWCC feedback, in bits per second. It stays
+	// zero unless the publisher sends that feedback inbound, which is not
+	// the conventional direction for it (see TrackStats).
+	BitrateEstimate uint64
+}
+
+// Stats returns the latest RTCP-derived quality metrics for the track.
+func (t *IncomingTrack) Stats() TrackStats {
+	t.statsMutex.Lock()
+	defer t.statsMutex.Unlock()
+	return t.stats
+}
+
+// OnRTCP registers a callback that is invoked with every RTCP packet
+// received for this track. Callbacks are invoked from the RTCP reading
+// goroutine and must not block.
+func (t *IncomingTrack) OnRTCP(cb func(rtcp.Packet)) {
+	t.statsMutex.Lock()
+	defer t.statsMutex.Unlock()
+	t.onRTCP = append(t.onRTCP, cb)
+}
+
+// processRTCP updates the track's stats from an incoming RTCP packet and
+// notifies any OnRTCP subscribers. It returns whether the packet indicates
+// high enough loss to warrant an immediate key frame request.
+func (t *IncomingTrack) processRTCP(pkt rtcp.Packet) (highLoss bool) {
+	t.statsMutex.Lock()
+
+	highLoss = updateTrackStats(&t.stats, uint32(t.track.SSRC()), pkt)
+
+	subscribers := make([]func(rtcp.Packet), len(t.onRTCP))
+	copy(subscribers, t.onRTCP)
+
+	t.statsMutex.Unlock()
+
+	for _, cb := range subscribers {
+		cb(pkt)
+	}
+
+	return highLoss
+}
+
+// updateTrackStats applies a single incoming RTCP packet to stats, filtering
+// by trackSSRC where the packet type carries more than one SSRC. It is
+// split out of processRTCP so it can be tested without a live TrackRemote.
+func updateTrackStats(stats *TrackStats, trackSSRC uint32, pkt rtcp.Packet) (highLoss bool) {
+	switch v := pkt.(type) {
+	case *rtcp.ReceiverReport:
+		for _, report := range v.Reports {
+			if report.SSRC != trackSSRC {
+				continue
+			}
+
+			stats.FractionLost = report.FractionLost
+			stats.PacketsLost = report.TotalLost
+			stats.Jitter = report.Jitter
+
+			if report.FractionLost >= fractionLostKeyFrameThreshold {
+				highLoss = true
+			}
+		}
+
+	case *rtcp.SenderReport:
+		if v.SSRC == trackSSRC {
+			stats.LastSenderReport = time.Now()
+		}
+
+	case *rtcp.ReceiverEstimatedMaximumBitrate:
+		// REMB is normally sent by the receiver (mediamtx) to the sender,
+		// not the other way around, so a well-behaved publisher will not
+		// emit this inbound; handled here only in case one does. A REMB
+		// packet applies to a set of SSRCs, not necessarily this track's,
+		// so it must be filtered the same way ReceiverReport is.
+		for _, ssrc := range v.SSRCs {
+			if ssrc == trackSSRC {
+				stats.BitrateEstimate = uint64(v.Bitrate)
+				break
+			}
+		}
+
+	case *rtcp.TransportLayerCC:
+		// like REMB, TWCC feedback is normally sent by the receiver to the
+		// sender, so it is not expected inbound here either. It also
+		// carries per-packet arrival data rather than a ready-made
+		// bitrate; a full bandwidth estimator is out of scope here, so it
+		// is only forwarded to OnRTCP subscribers.
+	}
+
+	return highLoss
+}
@@ -0,0 +1,124 @@
+package webrtc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pion/rtcp"
+)
+
+func TestUpdateTrackStatsReceiverReport(t *testing.T) {
+	for _, ca := range []struct {
+		name     string
+		ssrc     uint32
+		report   rtcp.ReceiverReport
+		highLoss bool
+	}{
+		{
+			"matching SSRC below loss threshold",
+			1234,
+			rtcp.ReceiverReport{
+				Reports: []rtcp.ReceptionReport{
+					{SSRC: 1234, FractionLost: 10, TotalLost: 3, Jitter: 42},
+				},
+			},
+			false,
+		},
+		{
+			"matching SSRC at loss threshold",
+			1234,
+			rtcp.ReceiverReport{
+				Reports: []rtcp.ReceptionReport{
+					{SSRC: 1234, FractionLost: fractionLostKeyFrameThreshold, TotalLost: 9, Jitter: 7},
+				},
+			},
+			true,
+		},
+		{
+			"report for a different SSRC is ignored",
+			1234,
+			rtcp.ReceiverReport{
+				Reports: []rtcp.ReceptionReport{
+					{SSRC: 5678, FractionLost: fractionLostKeyFrameThreshold, TotalLost: 9, Jitter: 7},
+				},
+			},
+			false,
+		},
+	} {
+		t.Run(ca.name, func(t *testing.T) {
+			var stats TrackStats
+			highLoss := updateTrackStats(&stats, ca.ssrc, &ca.report)
+
+			if highLoss != ca.highLoss {
+				t.Errorf("highLoss = %v, want %v", highLoss, ca.highLoss)
+			}
+
+			if ca.ssrc == ca.report.Reports[0].SSRC {
+				if stats.FractionLost != ca.report.Reports[0].FractionLost {
+					t.Errorf("FractionLost = %v, want %v", stats.FractionLost, ca.report.Reports[0].FractionLost)
+				}
+				if stats.PacketsLost != ca.report.Reports[0].TotalLost {
+					t.Errorf("PacketsLost = %v, want %v", stats.PacketsLost, ca.report.Reports[0].TotalLost)
+				}
+				if stats.Jitter != ca.report.Reports[0].Jitter {
+					t.Errorf("Jitter = %v, want %v", stats.Jitter, ca.report.Reports[0].Jitter)
+				}
+			} else if stats.FractionLost != 0 || stats.PacketsLost != 0 || stats.Jitter != 0 {
+				t.Errorf("stats were updated from a report for a different SSRC: %+v", stats)
+			}
+		})
+	}
+}
+
+func TestUpdateTrackStatsSenderReport(t *testing.T) {
+	var stats TrackStats
+
+	updateTrackStats(&stats, 1234, &rtcp.SenderReport{SSRC: 5678})
+	if !stats.LastSenderReport.IsZero() {
+		t.Fatalf("LastSenderReport should not be set for a non-matching SSRC")
+	}
+
+	before := time.Now()
+	updateTrackStats(&stats, 1234, &rtcp.SenderReport{SSRC: 1234})
+	if stats.LastSenderReport.Before(before) {
+		t.Fatalf("LastSenderReport was not updated to the current time")
+	}
+}
+
+func TestUpdateTrackStatsREMB(t *testing.T) {
+	for _, ca := range []struct {
+		name     string
+		ssrc     uint32
+		pkt      rtcp.ReceiverEstimatedMaximumBitrate
+		wantRate uint64
+	}{
+		{
+			"matching SSRC",
+			1234,
+			rtcp.ReceiverEstimatedMaximumBitrate{SSRCs: []uint32{1234}, Bitrate: 1_500_000},
+			1_500_000,
+		},
+		{
+			"non-matching SSRC is ignored",
+			1234,
+			rtcp.ReceiverEstimatedMaximumBitrate{SSRCs: []uint32{5678}, Bitrate: 1_500_000},
+			0,
+		},
+		// NOTE: Bitrate is float32; 1_500_000 survives float32 round-trip
+		// exactly since it is well within float32's integer precision range.
+	} {
+		t.Run(ca.name, func(t *testing.T) {
+			var stats TrackStats
+			updateTrackStats(&stats, ca.ssrc, &ca.pkt)
+
+			if stats.BitrateEstimate != ca.wantRate {
+				t.Errorf("BitrateEstimate = %v, want %v", stats.BitrateEstimate, ca.wantRate)
+			}
+		})
+	}
+}
+
+func TestUpdateTrackStatsTWCCDoesNotPanic(t *testing.T) {
+	var stats TrackStats
+	updateTrackStats(&stats, 1234, &rtcp.TransportLayerCC{})
+}